@@ -0,0 +1,65 @@
+package encache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMapCacheImplSetMultiGetMulti(t *testing.T) {
+	cache := NewMapCacheImpl()
+
+	err := cache.SetMulti(map[string]CacheSetEntry{
+		"a": {Value: intValues(1), Expiry: time.Minute},
+		"b": {Value: intValues(2), Expiry: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("SetMulti: %v", err)
+	}
+
+	results, err := cache.GetMulti([]string{"a", "b", "missing"}, nil)
+	if err != nil {
+		t.Fatalf("GetMulti: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("GetMulti returned %d entries, want 2: %v", len(results), results)
+	}
+	if got := results["a"][0].Interface(); got != 1 {
+		t.Errorf("results[a] = %v, want 1", got)
+	}
+	if got := results["b"][0].Interface(); got != 2 {
+		t.Errorf("results[b] = %v, want 2", got)
+	}
+	if _, ok := results["missing"]; ok {
+		t.Errorf("expected no entry for %q", "missing")
+	}
+}
+
+func TestMapCacheImplDeleteMulti(t *testing.T) {
+	cache := NewMapCacheImpl()
+
+	mustSet(t, cache, "a", 1)
+	mustSet(t, cache, "b", 2)
+
+	if err := cache.DeleteMulti([]string{"a", "missing"}); err != nil {
+		t.Fatalf("DeleteMulti: %v", err)
+	}
+
+	if _, found, _ := cache.Get("a", nil); found {
+		t.Errorf("expected %q to have been deleted", "a")
+	}
+	if _, found, _ := cache.Get("b", nil); !found {
+		t.Errorf("expected %q to still be cached", "b")
+	}
+}
+
+func TestMapCacheImplSetMultiRespectsMaxEntries(t *testing.T) {
+	cache := NewMapCacheImpl(WithMaxEntries(1), WithStrictFull())
+
+	err := cache.SetMulti(map[string]CacheSetEntry{
+		"a": {Value: intValues(1), Expiry: time.Minute},
+		"b": {Value: intValues(2), Expiry: time.Minute},
+	})
+	if err != ErrCacheFull {
+		t.Errorf("SetMulti = %v, want ErrCacheFull", err)
+	}
+}
@@ -0,0 +1,37 @@
+package encache
+
+import (
+	"reflect"
+	"time"
+)
+
+// Cache is the contract every backend adapter (map, redis, memcached, ...)
+// must satisfy so memoized functions can be cached interchangeably across
+// them.
+type Cache interface {
+	Get(key string, fType reflect.Type) ([]reflect.Value, bool, error)
+	Set(key string, value []reflect.Value, expiry time.Duration) error
+	Serialize(res []reflect.Value) (string, error)
+	Deserialize(serializedResult string, fType reflect.Type) ([]reflect.Value, error)
+	Expire(key string, expiry time.Duration) error
+	PeriodicExpire(runOnDuration time.Duration)
+
+	GetMulti(keys []string, fType reflect.Type) (map[string][]reflect.Value, error)
+	SetMulti(entries map[string]CacheSetEntry) error
+	DeleteMulti(keys []string) error
+
+	Incr(key string, delta int64, expiry time.Duration) (int64, error)
+	Decr(key string, delta int64, expiry time.Duration) (int64, error)
+}
+
+// CacheSetEntry is the value and expiry for a single key in a SetMulti
+// call, so callers can batch writes with independent TTLs in one call.
+type CacheSetEntry struct {
+	Value  []reflect.Value
+	Expiry time.Duration
+}
+
+var (
+	_ Cache = (*MapCacheImpl)(nil)
+	_ Cache = (*RedisCacheImpl)(nil)
+)
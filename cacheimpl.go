@@ -1,58 +1,232 @@
 package encache
 
 import (
+	"container/list"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrCacheFull is returned by MapCacheImpl.Set when the cache has reached
+// its maxEntries bound and was constructed with WithStrictFull.
+var ErrCacheFull = errors.New("encache: cache is full")
+
+// ErrNotInteger is returned by MapCacheImpl.Incr/Decr when the existing
+// value stored under the key is not a single int64.
+var ErrNotInteger = errors.New("encache: cached value is not an integer")
+
 type MapCacheImpl struct {
-	cache map[string]cacheEntry
+	mu         sync.Mutex
+	cache      map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+	strict     bool
 }
 
-type cacheEntry struct {
+type mapCacheEntry struct {
+	key        string
 	value      []reflect.Value
 	expiryTime time.Time
 }
 
-// for slice
-// size[0] is length and size[1] is capacity
-// if size[1] not passed length and capacity are both equal to size[0]
+// MapCacheOption configures optional behaviour of a MapCacheImpl.
+type MapCacheOption func(*MapCacheImpl)
 
-// for map
-// size[0] is the size
-func NewMapCacheImpl(size ...int) *MapCacheImpl {
-	if len(size) > 1 {
-		panic("too many arguments")
+// WithInitialSize preallocates capacity for size entries.
+func WithInitialSize(size int) MapCacheOption {
+	return func(cacheImpl *MapCacheImpl) {
+		cacheImpl.cache = make(map[string]*list.Element, size)
 	}
-	var cache map[string]cacheEntry
-	if len(size) > 0 {
-		cache = make(map[string]cacheEntry, size[0])
-	} else {
-		cache = make(map[string]cacheEntry)
+}
+
+// WithMaxEntries bounds the cache to at most maxEntries entries. Once
+// full, Set evicts the least-recently-used entry, unless WithStrictFull
+// is also given, in which case Set returns ErrCacheFull instead.
+func WithMaxEntries(maxEntries int) MapCacheOption {
+	return func(cacheImpl *MapCacheImpl) {
+		cacheImpl.maxEntries = maxEntries
 	}
+}
 
-	return &MapCacheImpl{
-		cache: cache,
+// WithStrictFull makes Set return ErrCacheFull instead of evicting the
+// least-recently-used entry once the cache reaches maxEntries.
+func WithStrictFull() MapCacheOption {
+	return func(cacheImpl *MapCacheImpl) {
+		cacheImpl.strict = true
 	}
 }
 
+func NewMapCacheImpl(opts ...MapCacheOption) *MapCacheImpl {
+	cacheImpl := &MapCacheImpl{
+		cache: make(map[string]*list.Element),
+		lru:   list.New(),
+	}
+	for _, opt := range opts {
+		opt(cacheImpl)
+	}
+	return cacheImpl
+}
+
 func (cacheImpl *MapCacheImpl) Get(key string, _ reflect.Type) ([]reflect.Value, bool, error) {
-	if res, ok := cacheImpl.cache[key]; ok && res.expiryTime.After(time.Now()) {
-		return res.value, true, nil
+	cacheImpl.mu.Lock()
+	defer cacheImpl.mu.Unlock()
+
+	elem, ok := cacheImpl.cache[key]
+	if !ok {
+		return nil, false, nil
 	}
-	return nil, false, nil
+
+	entry := elem.Value.(*mapCacheEntry)
+	if !entry.expiryTime.After(time.Now()) {
+		return nil, false, nil
+	}
+
+	cacheImpl.lru.MoveToFront(elem)
+	return entry.value, true, nil
 }
 
 func (cacheImpl *MapCacheImpl) Set(key string, value []reflect.Value, expiry time.Duration) error {
-	cacheImpl.cache[key] = cacheEntry{
+	cacheImpl.mu.Lock()
+	defer cacheImpl.mu.Unlock()
+
+	return cacheImpl.setLocked(key, value, expiry)
+}
+
+// setLocked is Set's body with the lock already held, so callers that
+// need to read-modify-write an entry (e.g. Incr) can do so atomically.
+func (cacheImpl *MapCacheImpl) setLocked(key string, value []reflect.Value, expiry time.Duration) error {
+	if elem, ok := cacheImpl.cache[key]; ok {
+		entry := elem.Value.(*mapCacheEntry)
+		entry.value = value
+		entry.expiryTime = time.Now().Add(expiry)
+		cacheImpl.lru.MoveToFront(elem)
+		return nil
+	}
+
+	if cacheImpl.maxEntries > 0 && len(cacheImpl.cache) >= cacheImpl.maxEntries {
+		if cacheImpl.strict {
+			return ErrCacheFull
+		}
+		cacheImpl.evictOldest()
+	}
+
+	elem := cacheImpl.lru.PushFront(&mapCacheEntry{
+		key:        key,
 		value:      value,
 		expiryTime: time.Now().Add(expiry),
+	})
+	cacheImpl.cache[key] = elem
+	return nil
+}
+
+// evictOldest removes the least-recently-used entry. Callers must hold
+// cacheImpl.mu.
+func (cacheImpl *MapCacheImpl) evictOldest() {
+	oldest := cacheImpl.lru.Back()
+	if oldest == nil {
+		return
+	}
+	cacheImpl.lru.Remove(oldest)
+	delete(cacheImpl.cache, oldest.Value.(*mapCacheEntry).key)
+}
+
+func (cacheImpl *MapCacheImpl) GetMulti(keys []string, fType reflect.Type) (map[string][]reflect.Value, error) {
+	cacheImpl.mu.Lock()
+	defer cacheImpl.mu.Unlock()
+
+	results := make(map[string][]reflect.Value, len(keys))
+	for _, key := range keys {
+		elem, ok := cacheImpl.cache[key]
+		if !ok {
+			continue
+		}
+
+		entry := elem.Value.(*mapCacheEntry)
+		if !entry.expiryTime.After(time.Now()) {
+			continue
+		}
+
+		cacheImpl.lru.MoveToFront(elem)
+		results[key] = entry.value
+	}
+	return results, nil
+}
+
+func (cacheImpl *MapCacheImpl) SetMulti(entries map[string]CacheSetEntry) error {
+	cacheImpl.mu.Lock()
+	defer cacheImpl.mu.Unlock()
+
+	for key, entry := range entries {
+		if elem, ok := cacheImpl.cache[key]; ok {
+			mapEntry := elem.Value.(*mapCacheEntry)
+			mapEntry.value = entry.Value
+			mapEntry.expiryTime = time.Now().Add(entry.Expiry)
+			cacheImpl.lru.MoveToFront(elem)
+			continue
+		}
+
+		if cacheImpl.maxEntries > 0 && len(cacheImpl.cache) >= cacheImpl.maxEntries {
+			if cacheImpl.strict {
+				return ErrCacheFull
+			}
+			cacheImpl.evictOldest()
+		}
+
+		elem := cacheImpl.lru.PushFront(&mapCacheEntry{
+			key:        key,
+			value:      entry.Value,
+			expiryTime: time.Now().Add(entry.Expiry),
+		})
+		cacheImpl.cache[key] = elem
+	}
+	return nil
+}
+
+// Incr adds delta to the int64 stored under key (treating a missing or
+// expired key as 0), stores the result with expiry and returns it. It
+// returns ErrNotInteger if the existing value isn't a single int64.
+func (cacheImpl *MapCacheImpl) Incr(key string, delta int64, expiry time.Duration) (int64, error) {
+	cacheImpl.mu.Lock()
+	defer cacheImpl.mu.Unlock()
+
+	var current int64
+	if elem, ok := cacheImpl.cache[key]; ok {
+		entry := elem.Value.(*mapCacheEntry)
+		if entry.expiryTime.After(time.Now()) {
+			if len(entry.value) != 1 || entry.value[0].Kind() != reflect.Int64 {
+				return 0, ErrNotInteger
+			}
+			current = entry.value[0].Int()
+		}
+	}
+
+	newValue := current + delta
+	if err := cacheImpl.setLocked(key, []reflect.Value{reflect.ValueOf(newValue)}, expiry); err != nil {
+		return 0, err
+	}
+	return newValue, nil
+}
+
+// Decr subtracts delta from the int64 stored under key. See Incr.
+func (cacheImpl *MapCacheImpl) Decr(key string, delta int64, expiry time.Duration) (int64, error) {
+	return cacheImpl.Incr(key, -delta, expiry)
+}
+
+func (cacheImpl *MapCacheImpl) DeleteMulti(keys []string) error {
+	cacheImpl.mu.Lock()
+	defer cacheImpl.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := cacheImpl.cache[key]; ok {
+			cacheImpl.lru.Remove(elem)
+			delete(cacheImpl.cache, key)
+		}
 	}
 	return nil
 }
@@ -69,33 +243,23 @@ func (cacheImpl *MapCacheImpl) Deserialize(serializedResult string, fType reflec
 
 // expire after a certain duration
 func (cacheImpl *MapCacheImpl) Expire(key string, expiry time.Duration) error {
-	muLockImpl := NewMuLockImpl()
-	lockerr := muLockImpl.lock()
-	if lockerr != nil {
-		// unreachable
-		log.Println("error in lock: ", lockerr)
-		panic("error in lock: " + lockerr.Error())
-	}
-	defer func() {
-		unlockerr := muLockImpl.unlock()
-		if unlockerr != nil {
-			// unreachable
-			log.Println("error in unlock: ", unlockerr)
-			panic("error in unlock: " + unlockerr.Error())
-		}
-	}()
+	cacheImpl.mu.Lock()
+	defer cacheImpl.mu.Unlock()
 
-	cacheEntry, ok := cacheImpl.cache[key]
-	if ok {
-		if expiry <= 0 {
-			delete(cacheImpl.cache, key)
-			return nil
-		} else {
-			seterr := cacheImpl.Set(key, cacheEntry.value, expiry)
-			return seterr
-		}
+	elem, ok := cacheImpl.cache[key]
+	if !ok {
+		return nil
 	}
 
+	if expiry <= 0 {
+		cacheImpl.lru.Remove(elem)
+		delete(cacheImpl.cache, key)
+		return nil
+	}
+
+	entry := elem.Value.(*mapCacheEntry)
+	entry.expiryTime = time.Now().Add(expiry)
+	cacheImpl.lru.MoveToFront(elem)
 	return nil
 }
 
@@ -104,12 +268,19 @@ func (cacheImpl *MapCacheImpl) PeriodicExpire(runOnDuration time.Duration) {
 	go func() {
 		for {
 			time.Sleep(runOnDuration)
-			for key, entry := range cacheImpl.cache {
-				if entry.expiryTime.Before(time.Now()) {
-					err := cacheImpl.Expire(key, 0)
-					if err != nil {
-						log.Println("error in periodic expire: ", err)
-					}
+
+			cacheImpl.mu.Lock()
+			expiredKeys := make([]string, 0)
+			for key, elem := range cacheImpl.cache {
+				if elem.Value.(*mapCacheEntry).expiryTime.Before(time.Now()) {
+					expiredKeys = append(expiredKeys, key)
+				}
+			}
+			cacheImpl.mu.Unlock()
+
+			for _, key := range expiredKeys {
+				if err := cacheImpl.Expire(key, 0); err != nil {
+					log.Println("error in periodic expire: ", err)
 				}
 			}
 		}
@@ -118,16 +289,33 @@ func (cacheImpl *MapCacheImpl) PeriodicExpire(runOnDuration time.Duration) {
 
 type RedisCacheImpl struct {
 	client redis.UniversalClient
+	codec  Codec
 }
 
-func NewRedisCacheImpl(client redis.UniversalClient) *RedisCacheImpl {
-	return &RedisCacheImpl{
+// RedisCacheOption configures optional behaviour of a RedisCacheImpl.
+type RedisCacheOption func(*RedisCacheImpl)
+
+// WithCodec selects the Codec used to serialize cached values. Defaults
+// to JSONCodec when not supplied, preserving the original behaviour.
+func WithCodec(codec Codec) RedisCacheOption {
+	return func(cacheImpl *RedisCacheImpl) {
+		cacheImpl.codec = codec
+	}
+}
+
+func NewRedisCacheImpl(client redis.UniversalClient, opts ...RedisCacheOption) *RedisCacheImpl {
+	cacheImpl := &RedisCacheImpl{
 		client: client,
+		codec:  NewJSONCodec(),
+	}
+	for _, opt := range opts {
+		opt(cacheImpl)
 	}
+	return cacheImpl
 }
 
 func (cacheImpl *RedisCacheImpl) Serialize(res []reflect.Value) (string, error) {
-	serializedRes, err := json.Marshal(res)
+	serializedRes, err := cacheImpl.codec.Encode(res)
 	if err != nil {
 		return "", err
 	}
@@ -135,18 +323,7 @@ func (cacheImpl *RedisCacheImpl) Serialize(res []reflect.Value) (string, error)
 }
 
 func (cacheImpl *RedisCacheImpl) Deserialize(serializedResult string, fType reflect.Type) ([]reflect.Value, error) {
-	var results []interface{}
-	err := json.Unmarshal([]byte(serializedResult), &results)
-	if err != nil {
-		return nil, err
-	}
-
-	res := make([]reflect.Value, len(results))
-	for i := range results {
-		res[i] = reflect.New(fType.Out(i)).Elem()
-		res[i].Set(reflect.ValueOf(results[i]))
-	}
-	return res, nil
+	return cacheImpl.codec.Decode([]byte(serializedResult), fType)
 }
 
 func (cacheImpl *RedisCacheImpl) Get(key string, fType reflect.Type) ([]reflect.Value, bool, error) {
@@ -193,6 +370,96 @@ func (cacheImpl *RedisCacheImpl) Expire(key string, expiry time.Duration) error
 	return err
 }
 
+// GetMulti fetches all keys in a single MGET round trip.
+func (cacheImpl *RedisCacheImpl) GetMulti(keys []string, fType reflect.Type) (map[string][]reflect.Value, error) {
+	ctx := context.Background()
+
+	rawValues, err := cacheImpl.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string][]reflect.Value, len(keys))
+	for i, raw := range rawValues {
+		if raw == nil {
+			continue
+		}
+
+		cachedResult, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("encache: unexpected MGET value type %T for key %q", raw, keys[i])
+		}
+
+		returnValue, err := cacheImpl.Deserialize(cachedResult, fType)
+		if err != nil {
+			return nil, err
+		}
+		results[keys[i]] = returnValue
+	}
+	return results, nil
+}
+
+// SetMulti writes every entry with its own TTL using a single pipeline,
+// eliminating a round trip per key.
+func (cacheImpl *RedisCacheImpl) SetMulti(entries map[string]CacheSetEntry) error {
+	ctx := context.Background()
+
+	_, err := cacheImpl.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, entry := range entries {
+			serializedResult, err := cacheImpl.Serialize(entry.Value)
+			if err != nil {
+				return err
+			}
+			pipe.Set(ctx, key, serializedResult, entry.Expiry)
+		}
+		return nil
+	})
+	return err
+}
+
+// DeleteMulti removes every key in a single DEL round trip.
+func (cacheImpl *RedisCacheImpl) DeleteMulti(keys []string) error {
+	ctx := context.Background()
+	return cacheImpl.client.Del(ctx, keys...).Err()
+}
+
+// Incr applies delta to key atomically server-side via INCRBY, in a
+// single pipeline. When expiry > 0, it sets the TTL only if key doesn't
+// already have one (EXPIRE ... NX), so a fixed window established by the
+// first Incr isn't pushed back by every call after it; expiry <= 0 leaves
+// the key's TTL untouched rather than deleting it (EXPIRE key 0 would).
+func (cacheImpl *RedisCacheImpl) Incr(key string, delta int64, expiry time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	pipe := cacheImpl.client.Pipeline()
+	incr := pipe.IncrBy(ctx, key, delta)
+	if expiry > 0 {
+		pipe.ExpireNX(ctx, key, expiry)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// Decr applies -delta to key atomically server-side via DECRBY. See Incr
+// for the expiry handling.
+func (cacheImpl *RedisCacheImpl) Decr(key string, delta int64, expiry time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	pipe := cacheImpl.client.Pipeline()
+	decr := pipe.DecrBy(ctx, key, delta)
+	if expiry > 0 {
+		pipe.ExpireNX(ctx, key, expiry)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return decr.Val(), nil
+}
+
 type CacheKeyImpl struct{}
 
 func NewDefaultCacheKeyImpl() *CacheKeyImpl {
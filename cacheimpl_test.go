@@ -0,0 +1,67 @@
+package encache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intValues(n int) []reflect.Value {
+	return []reflect.Value{reflect.ValueOf(n)}
+}
+
+func TestMapCacheImplLRUEviction(t *testing.T) {
+	cache := NewMapCacheImpl(WithMaxEntries(2))
+
+	mustSet(t, cache, "a", 1)
+	mustSet(t, cache, "b", 2)
+	// "a" is now the least-recently-used of {a, b}.
+	mustSet(t, cache, "c", 3)
+
+	if _, found, _ := cache.Get("a", nil); found {
+		t.Errorf("expected %q to have been evicted", "a")
+	}
+	if _, found, _ := cache.Get("b", nil); !found {
+		t.Errorf("expected %q to still be cached", "b")
+	}
+	if _, found, _ := cache.Get("c", nil); !found {
+		t.Errorf("expected %q to still be cached", "c")
+	}
+}
+
+func TestMapCacheImplMRUPromotionOnGet(t *testing.T) {
+	cache := NewMapCacheImpl(WithMaxEntries(2))
+
+	mustSet(t, cache, "a", 1)
+	mustSet(t, cache, "b", 2)
+
+	// Touch "a" so "b" becomes the least-recently-used entry.
+	if _, found, _ := cache.Get("a", nil); !found {
+		t.Fatalf("expected %q to be cached", "a")
+	}
+
+	mustSet(t, cache, "c", 3)
+
+	if _, found, _ := cache.Get("b", nil); found {
+		t.Errorf("expected %q to have been evicted after being touched least recently", "b")
+	}
+	if _, found, _ := cache.Get("a", nil); !found {
+		t.Errorf("expected %q to still be cached after being promoted", "a")
+	}
+}
+
+func TestMapCacheImplSetFullStrict(t *testing.T) {
+	cache := NewMapCacheImpl(WithMaxEntries(1), WithStrictFull())
+
+	mustSet(t, cache, "a", 1)
+	if err := cache.Set("b", intValues(2), time.Minute); err != ErrCacheFull {
+		t.Errorf("Set(b) = %v, want ErrCacheFull", err)
+	}
+}
+
+func mustSet(t *testing.T, cache *MapCacheImpl, key string, value int) {
+	t.Helper()
+	if err := cache.Set(key, intValues(value), time.Minute); err != nil {
+		t.Fatalf("Set(%q): %v", key, err)
+	}
+}
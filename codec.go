@@ -0,0 +1,203 @@
+package encache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// errorType is the built-in error interface type. Every codec below
+// special-cases it: none of JSON, gob or msgpack can decode straight into
+// an interface destination (JSON/msgpack lose unexported fields and gob
+// needs gob.Register on the concrete type), and error is also the single
+// interface-typed return virtually every memoized function has.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// errorEnvelope is the wire representation codecs use for error-typed
+// outputs in place of the error value itself.
+type errorEnvelope struct {
+	HasError bool
+	Message  string
+}
+
+func newErrorEnvelope(v reflect.Value) errorEnvelope {
+	err, _ := v.Interface().(error)
+	if err == nil {
+		return errorEnvelope{}
+	}
+	return errorEnvelope{HasError: true, Message: err.Error()}
+}
+
+// toValue reverses newErrorEnvelope, reconstructing a plain errors.New
+// error that preserves the original message but not its concrete type.
+func (e errorEnvelope) toValue() reflect.Value {
+	result := reflect.New(errorType).Elem()
+	if e.HasError {
+		result.Set(reflect.ValueOf(errors.New(e.Message)))
+	}
+	return result
+}
+
+// Codec encodes and decodes the []reflect.Value results of a memoized
+// function so a Cache backend can store them as bytes and reconstruct
+// the original concrete types (structs, maps, time.Time, ...) on read,
+// rather than the interface{} values json.Unmarshal alone would produce.
+type Codec interface {
+	Encode(values []reflect.Value) ([]byte, error)
+	Decode(data []byte, fType reflect.Type) ([]reflect.Value, error)
+}
+
+// JSONCodec is the default Codec, kept for backwards compatibility with
+// callers relying on RedisCacheImpl's original JSON-only behaviour.
+type JSONCodec struct{}
+
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{}
+}
+
+func (c *JSONCodec) Encode(values []reflect.Value) ([]byte, error) {
+	raw := make([]interface{}, len(values))
+	for i, v := range values {
+		if v.Type() == errorType {
+			raw[i] = newErrorEnvelope(v)
+			continue
+		}
+		raw[i] = v.Interface()
+	}
+	return json.Marshal(raw)
+}
+
+func (c *JSONCodec) Decode(data []byte, fType reflect.Type) ([]reflect.Value, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	values := make([]reflect.Value, len(raw))
+	for i := range raw {
+		outType := fType.Out(i)
+		if outType == errorType {
+			var envelope errorEnvelope
+			if err := json.Unmarshal(raw[i], &envelope); err != nil {
+				return nil, err
+			}
+			values[i] = envelope.toValue()
+			continue
+		}
+
+		ptr := reflect.New(outType)
+		if err := json.Unmarshal(raw[i], ptr.Interface()); err != nil {
+			return nil, err
+		}
+		values[i] = ptr.Elem()
+	}
+	return values, nil
+}
+
+// GobCodec encodes with encoding/gob, Go's native binary format, and
+// decodes straight into the concrete output type. Like JSONCodec, it
+// encodes only exported struct fields.
+type GobCodec struct{}
+
+func NewGobCodec() *GobCodec {
+	return &GobCodec{}
+}
+
+func (c *GobCodec) Encode(values []reflect.Value) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	for _, v := range values {
+		if v.Type() == errorType {
+			if err := enc.Encode(newErrorEnvelope(v)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if v.Kind() == reflect.Interface {
+			return nil, fmt.Errorf("encache: GobCodec cannot encode interface-typed value of type %s; use JSONCodec or MsgpackCodec instead", v.Type())
+		}
+		if err := enc.Encode(v.Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *GobCodec) Decode(data []byte, fType reflect.Type) ([]reflect.Value, error) {
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	values := make([]reflect.Value, fType.NumOut())
+	for i := range values {
+		outType := fType.Out(i)
+		if outType == errorType {
+			var envelope errorEnvelope
+			if err := dec.Decode(&envelope); err != nil {
+				return nil, err
+			}
+			values[i] = envelope.toValue()
+			continue
+		}
+		if outType.Kind() == reflect.Interface {
+			return nil, fmt.Errorf("encache: GobCodec cannot decode into interface-typed output %s; use JSONCodec or MsgpackCodec instead", outType)
+		}
+
+		ptr := reflect.New(outType)
+		if err := dec.Decode(ptr.Interface()); err != nil {
+			return nil, err
+		}
+		values[i] = ptr.Elem()
+	}
+	return values, nil
+}
+
+// MsgpackCodec encodes with msgpack, which is more compact than JSON on
+// the wire and, like GobCodec, decodes straight into the concrete output
+// type instead of losing numeric precision to interface{}/float64.
+type MsgpackCodec struct{}
+
+func NewMsgpackCodec() *MsgpackCodec {
+	return &MsgpackCodec{}
+}
+
+func (c *MsgpackCodec) Encode(values []reflect.Value) ([]byte, error) {
+	raw := make([]interface{}, len(values))
+	for i, v := range values {
+		if v.Type() == errorType {
+			raw[i] = newErrorEnvelope(v)
+			continue
+		}
+		raw[i] = v.Interface()
+	}
+	return msgpack.Marshal(raw)
+}
+
+func (c *MsgpackCodec) Decode(data []byte, fType reflect.Type) ([]reflect.Value, error) {
+	var raw []msgpack.RawMessage
+	if err := msgpack.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	values := make([]reflect.Value, len(raw))
+	for i := range raw {
+		outType := fType.Out(i)
+		if outType == errorType {
+			var envelope errorEnvelope
+			if err := msgpack.Unmarshal(raw[i], &envelope); err != nil {
+				return nil, err
+			}
+			values[i] = envelope.toValue()
+			continue
+		}
+
+		ptr := reflect.New(outType)
+		if err := msgpack.Unmarshal(raw[i], ptr.Interface()); err != nil {
+			return nil, err
+		}
+		values[i] = ptr.Elem()
+	}
+	return values, nil
+}
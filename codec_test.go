@@ -0,0 +1,72 @@
+package encache
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type sampleRecord struct {
+	Name      string
+	CreatedAt time.Time
+}
+
+var sampleFuncType = reflect.TypeOf(func() (sampleRecord, error) { return sampleRecord{}, nil })
+
+func errorValue(err error) reflect.Value {
+	v := reflect.New(errorType).Elem()
+	if err != nil {
+		v.Set(reflect.ValueOf(err))
+	}
+	return v
+}
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	testCodecRoundTrip(t, NewJSONCodec())
+}
+
+func TestGobCodecRoundTrip(t *testing.T) {
+	testCodecRoundTrip(t, NewGobCodec())
+}
+
+func TestMsgpackCodecRoundTrip(t *testing.T) {
+	testCodecRoundTrip(t, NewMsgpackCodec())
+}
+
+func testCodecRoundTrip(t *testing.T, codec Codec) {
+	t.Helper()
+
+	now := time.Date(2024, 3, 5, 12, 30, 0, 0, time.UTC)
+	record := sampleRecord{Name: "a", CreatedAt: now}
+
+	encoded, err := codec.Encode([]reflect.Value{reflect.ValueOf(record), errorValue(nil)})
+	if err != nil {
+		t.Fatalf("Encode(nil error): %v", err)
+	}
+	decoded, err := codec.Decode(encoded, sampleFuncType)
+	if err != nil {
+		t.Fatalf("Decode(nil error): %v", err)
+	}
+	if got := decoded[0].Interface().(sampleRecord); got.Name != record.Name || !got.CreatedAt.Equal(record.CreatedAt) {
+		t.Errorf("decoded record = %+v, want %+v", got, record)
+	}
+	if !decoded[1].IsNil() {
+		t.Errorf("decoded error = %v, want nil", decoded[1].Interface())
+	}
+
+	encoded, err = codec.Encode([]reflect.Value{reflect.ValueOf(record), errorValue(errors.New("boom"))})
+	if err != nil {
+		t.Fatalf("Encode(non-nil error): %v", err)
+	}
+	decoded, err = codec.Decode(encoded, sampleFuncType)
+	if err != nil {
+		t.Fatalf("Decode(non-nil error): %v", err)
+	}
+	if decoded[1].IsNil() {
+		t.Fatalf("decoded error is nil, want %q", "boom")
+	}
+	if got := decoded[1].Interface().(error).Error(); got != "boom" {
+		t.Errorf("decoded error = %q, want %q", got, "boom")
+	}
+}
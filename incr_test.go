@@ -0,0 +1,42 @@
+package encache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMapCacheImplIncrDecr(t *testing.T) {
+	cache := NewMapCacheImpl()
+
+	newValue, err := cache.Incr("counter", 5, time.Minute)
+	if err != nil {
+		t.Fatalf("Incr: %v", err)
+	}
+	if newValue != 5 {
+		t.Errorf("Incr = %d, want 5", newValue)
+	}
+
+	newValue, err = cache.Decr("counter", 2, time.Minute)
+	if err != nil {
+		t.Fatalf("Decr: %v", err)
+	}
+	if newValue != 3 {
+		t.Errorf("Decr = %d, want 3", newValue)
+	}
+}
+
+func TestMapCacheImplIncrOnNonIntegerValue(t *testing.T) {
+	cache := NewMapCacheImpl()
+	if err := cache.Set("key", stringValues("not a number"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if _, err := cache.Incr("key", 1, time.Minute); err != ErrNotInteger {
+		t.Errorf("Incr err = %v, want ErrNotInteger", err)
+	}
+}
+
+func stringValues(s string) []reflect.Value {
+	return []reflect.Value{reflect.ValueOf(s)}
+}
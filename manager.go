@@ -0,0 +1,154 @@
+package encache
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// CacheFactory builds a Cache adapter from raw configuration, so backends
+// can be selected by a config string rather than by code.
+type CacheFactory func(cfg json.RawMessage) (Cache, error)
+
+// CacheManager holds named adapter factories and hands out namespaced
+// sub-caches backed by them, e.g. cm.Cache("mempool").
+type CacheManager struct {
+	mu        sync.RWMutex
+	factories map[string]CacheFactory
+	caches    map[string]Cache
+}
+
+func NewCacheManager() *CacheManager {
+	return &CacheManager{
+		factories: make(map[string]CacheFactory),
+		caches:    make(map[string]Cache),
+	}
+}
+
+// Register adds an adapter factory under name, making it available to
+// Build.
+func (cm *CacheManager) Register(name string, factory CacheFactory) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.factories[name] = factory
+}
+
+// Build constructs the adapter registered under name from cfg and makes
+// it available to Cache under namespace.
+func (cm *CacheManager) Build(name string, namespace string, cfg json.RawMessage) error {
+	cm.mu.RLock()
+	factory, ok := cm.factories[name]
+	cm.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("encache: no cache adapter registered for %q", name)
+	}
+
+	adapter, err := factory(cfg)
+	if err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.caches[namespace] = adapter
+	return nil
+}
+
+// Cache returns the sub-cache for namespace, keying every entry under it
+// with "namespace/" so same-named memoized functions don't collide.
+func (cm *CacheManager) Cache(namespace string) Cache {
+	cm.mu.RLock()
+	adapter, ok := cm.caches[namespace]
+	cm.mu.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("encache: no cache built for namespace %q", namespace))
+	}
+
+	return &namespacedCache{
+		namespace: namespace,
+		adapter:   adapter,
+	}
+}
+
+// namespacedCache wraps a Cache adapter, prefixing every key with
+// "namespace/".
+type namespacedCache struct {
+	namespace string
+	adapter   Cache
+}
+
+func (c *namespacedCache) prefix(key string) string {
+	return c.namespace + "/" + key
+}
+
+func (c *namespacedCache) Get(key string, fType reflect.Type) ([]reflect.Value, bool, error) {
+	return c.adapter.Get(c.prefix(key), fType)
+}
+
+func (c *namespacedCache) Set(key string, value []reflect.Value, expiry time.Duration) error {
+	return c.adapter.Set(c.prefix(key), value, expiry)
+}
+
+func (c *namespacedCache) Serialize(res []reflect.Value) (string, error) {
+	return c.adapter.Serialize(res)
+}
+
+func (c *namespacedCache) Deserialize(serializedResult string, fType reflect.Type) ([]reflect.Value, error) {
+	return c.adapter.Deserialize(serializedResult, fType)
+}
+
+func (c *namespacedCache) Expire(key string, expiry time.Duration) error {
+	return c.adapter.Expire(c.prefix(key), expiry)
+}
+
+func (c *namespacedCache) PeriodicExpire(runOnDuration time.Duration) {
+	c.adapter.PeriodicExpire(runOnDuration)
+}
+
+func (c *namespacedCache) GetMulti(keys []string, fType reflect.Type) (map[string][]reflect.Value, error) {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix(key)
+	}
+
+	prefixedResults, err := c.adapter.GetMulti(prefixed, fType)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string][]reflect.Value, len(prefixedResults))
+	for i, key := range keys {
+		if value, ok := prefixedResults[prefixed[i]]; ok {
+			results[key] = value
+		}
+	}
+	return results, nil
+}
+
+func (c *namespacedCache) SetMulti(entries map[string]CacheSetEntry) error {
+	prefixedEntries := make(map[string]CacheSetEntry, len(entries))
+	for key, entry := range entries {
+		prefixedEntries[c.prefix(key)] = entry
+	}
+	return c.adapter.SetMulti(prefixedEntries)
+}
+
+func (c *namespacedCache) DeleteMulti(keys []string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix(key)
+	}
+	return c.adapter.DeleteMulti(prefixed)
+}
+
+func (c *namespacedCache) Incr(key string, delta int64, expiry time.Duration) (int64, error) {
+	return c.adapter.Incr(c.prefix(key), delta, expiry)
+}
+
+func (c *namespacedCache) Decr(key string, delta int64, expiry time.Duration) (int64, error) {
+	return c.adapter.Decr(c.prefix(key), delta, expiry)
+}
+
+var _ Cache = (*namespacedCache)(nil)
@@ -0,0 +1,70 @@
+package encache
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var intFuncType = reflect.TypeOf(func() (int, error) { return 0, nil })
+
+func TestCacheManagerNamespacesKeys(t *testing.T) {
+	shared := NewMapCacheImpl()
+
+	cm := NewCacheManager()
+	cm.Register("map", func(cfg json.RawMessage) (Cache, error) {
+		return shared, nil
+	})
+
+	if err := cm.Build("map", "alpha", nil); err != nil {
+		t.Fatalf("Build(alpha): %v", err)
+	}
+	if err := cm.Build("map", "beta", nil); err != nil {
+		t.Fatalf("Build(beta): %v", err)
+	}
+
+	alpha := cm.Cache("alpha")
+	beta := cm.Cache("beta")
+
+	if err := alpha.Set("x", []reflect.Value{reflect.ValueOf(1), errorValue(nil)}, time.Minute); err != nil {
+		t.Fatalf("alpha.Set: %v", err)
+	}
+
+	if _, found, err := beta.Get("x", intFuncType); err != nil {
+		t.Fatalf("beta.Get: %v", err)
+	} else if found {
+		t.Errorf("beta.Get(\"x\") found a value set via alpha; namespaces collided")
+	}
+
+	values, found, err := alpha.Get("x", intFuncType)
+	if err != nil {
+		t.Fatalf("alpha.Get: %v", err)
+	}
+	if !found {
+		t.Fatalf("alpha.Get(\"x\") = not found, want found")
+	}
+	if got := values[0].Interface().(int); got != 1 {
+		t.Errorf("alpha.Get(\"x\") = %d, want 1", got)
+	}
+
+	if _, found, err := shared.Get("x", intFuncType); err != nil {
+		t.Fatalf("shared.Get: %v", err)
+	} else if found {
+		t.Errorf("shared.Get(\"x\") found a value; want it only reachable under the \"alpha/\" prefix")
+	}
+	if _, found, err := shared.Get("alpha/x", intFuncType); err != nil {
+		t.Fatalf("shared.Get(\"alpha/x\"): %v", err)
+	} else if !found {
+		t.Errorf("shared.Get(\"alpha/x\") = not found, want found")
+	}
+}
+
+func TestCacheManagerCachePanicsOnUnbuiltNamespace(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Cache(\"missing\") did not panic")
+		}
+	}()
+	NewCacheManager().Cache("missing")
+}
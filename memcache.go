@@ -0,0 +1,219 @@
+package encache
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// MemcachedCacheImpl adapts a memcached client to the Cache interface, for
+// deployments (App Engine, Cloud Memorystore-style setups, ...) where
+// memcached is available but Redis is not.
+type MemcachedCacheImpl struct {
+	client *memcache.Client
+	codec  Codec
+}
+
+// MemcachedCacheOption configures optional behaviour of a MemcachedCacheImpl.
+type MemcachedCacheOption func(*MemcachedCacheImpl)
+
+// WithMemcachedCodec selects the Codec used to serialize cached values.
+// Defaults to JSONCodec when not supplied.
+func WithMemcachedCodec(codec Codec) MemcachedCacheOption {
+	return func(cacheImpl *MemcachedCacheImpl) {
+		cacheImpl.codec = codec
+	}
+}
+
+func NewMemcachedCacheImpl(client *memcache.Client, opts ...MemcachedCacheOption) *MemcachedCacheImpl {
+	cacheImpl := &MemcachedCacheImpl{
+		client: client,
+		codec:  NewJSONCodec(),
+	}
+	for _, opt := range opts {
+		opt(cacheImpl)
+	}
+	return cacheImpl
+}
+
+func (cacheImpl *MemcachedCacheImpl) Serialize(res []reflect.Value) (string, error) {
+	serializedRes, err := cacheImpl.codec.Encode(res)
+	if err != nil {
+		return "", err
+	}
+	return string(serializedRes), nil
+}
+
+func (cacheImpl *MemcachedCacheImpl) Deserialize(serializedResult string, fType reflect.Type) ([]reflect.Value, error) {
+	return cacheImpl.codec.Decode([]byte(serializedResult), fType)
+}
+
+func (cacheImpl *MemcachedCacheImpl) Get(key string, fType reflect.Type) ([]reflect.Value, bool, error) {
+	item, err := cacheImpl.client.Get(key)
+	if err != nil && err != memcache.ErrCacheMiss {
+		return nil, false, err
+	}
+	if err == memcache.ErrCacheMiss {
+		return nil, false, nil
+	}
+
+	returnValue, err := cacheImpl.Deserialize(string(item.Value), fType)
+	if err != nil {
+		return nil, false, err
+	}
+	return returnValue, true, nil
+}
+
+func (cacheImpl *MemcachedCacheImpl) Set(key string, value []reflect.Value, expiry time.Duration) error {
+	serializedResult, err := cacheImpl.Serialize(value)
+	if err != nil {
+		return err
+	}
+
+	return cacheImpl.client.Set(&memcache.Item{
+		Key:        key,
+		Value:      []byte(serializedResult),
+		Expiration: memcacheExpiration(expiry),
+	})
+}
+
+// Expire re-sets the item with a new TTL, since memcached has no equivalent
+// of Redis's typed EXPIRE on an existing key. It deletes the key outright
+// when expiry <= 0, matching MapCacheImpl.Expire.
+func (cacheImpl *MemcachedCacheImpl) Expire(key string, expiry time.Duration) error {
+	item, err := cacheImpl.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if expiry <= 0 {
+		return cacheImpl.client.Delete(key)
+	}
+
+	item.Expiration = memcacheExpiration(expiry)
+	return cacheImpl.client.Set(item)
+}
+
+// memcacheMaxRelativeExpiration is the cutoff past which memcached
+// reinterprets an Expiration as an absolute Unix timestamp rather than a
+// number of seconds from now.
+const memcacheMaxRelativeExpiration = 30 * 24 * time.Hour
+
+// memcacheExpiration converts expiry into the int32 memcached's
+// Expiration field expects, switching to an absolute Unix timestamp once
+// expiry exceeds the 30-day cutoff above, since passing a longer relative
+// duration through unchanged gets silently treated as a past timestamp.
+func memcacheExpiration(expiry time.Duration) int32 {
+	if expiry > memcacheMaxRelativeExpiration {
+		return int32(time.Now().Add(expiry).Unix())
+	}
+	return int32(expiry.Seconds())
+}
+
+// just to satisfy the interface as expirations happen automatically
+func (cacheImpl *MemcachedCacheImpl) PeriodicExpire(_ time.Duration) {}
+
+// GetMulti fetches all keys with a single memcached multi-get.
+func (cacheImpl *MemcachedCacheImpl) GetMulti(keys []string, fType reflect.Type) (map[string][]reflect.Value, error) {
+	items, err := cacheImpl.client.GetMulti(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string][]reflect.Value, len(items))
+	for key, item := range items {
+		returnValue, err := cacheImpl.Deserialize(string(item.Value), fType)
+		if err != nil {
+			return nil, err
+		}
+		results[key] = returnValue
+	}
+	return results, nil
+}
+
+// SetMulti writes every entry. gomemcache has no native batch write, so
+// this issues one Set per entry.
+func (cacheImpl *MemcachedCacheImpl) SetMulti(entries map[string]CacheSetEntry) error {
+	for key, entry := range entries {
+		if err := cacheImpl.Set(key, entry.Value, entry.Expiry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMulti removes every key. gomemcache has no native batch delete, so
+// this issues one Delete per key, ignoring cache misses.
+func (cacheImpl *MemcachedCacheImpl) DeleteMulti(keys []string) error {
+	for _, key := range keys {
+		if err := cacheImpl.client.Delete(key); err != nil && err != memcache.ErrCacheMiss {
+			return err
+		}
+	}
+	return nil
+}
+
+// Incr adds delta to the counter stored under key via memcached's native
+// INCR, initializing it to delta with expiry if the key doesn't exist yet.
+// If a concurrent first caller wins the race to create the key, Add fails
+// with ErrNotStored; Incr then retries the INCR once against the now
+// existing key rather than failing.
+func (cacheImpl *MemcachedCacheImpl) Incr(key string, delta int64, expiry time.Duration) (int64, error) {
+	newValue, err := cacheImpl.client.Increment(key, uint64(delta))
+	if err == memcache.ErrCacheMiss {
+		if err := cacheImpl.client.Add(&memcache.Item{
+			Key:        key,
+			Value:      []byte(strconv.FormatInt(delta, 10)),
+			Expiration: memcacheExpiration(expiry),
+		}); err == memcache.ErrNotStored {
+			newValue, err = cacheImpl.client.Increment(key, uint64(delta))
+			if err != nil {
+				return 0, err
+			}
+			return int64(newValue), nil
+		} else if err != nil {
+			return 0, err
+		}
+		return delta, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(newValue), nil
+}
+
+// Decr subtracts delta from the counter stored under key via memcached's
+// native DECR, initializing it to -delta with expiry if the key doesn't
+// exist yet. If a concurrent first caller wins the race to create the
+// key, Add fails with ErrNotStored; Decr then retries the DECR once
+// against the now existing key rather than failing.
+func (cacheImpl *MemcachedCacheImpl) Decr(key string, delta int64, expiry time.Duration) (int64, error) {
+	newValue, err := cacheImpl.client.Decrement(key, uint64(delta))
+	if err == memcache.ErrCacheMiss {
+		if err := cacheImpl.client.Add(&memcache.Item{
+			Key:        key,
+			Value:      []byte(strconv.FormatInt(-delta, 10)),
+			Expiration: memcacheExpiration(expiry),
+		}); err == memcache.ErrNotStored {
+			newValue, err = cacheImpl.client.Decrement(key, uint64(delta))
+			if err != nil {
+				return 0, err
+			}
+			return int64(newValue), nil
+		} else if err != nil {
+			return 0, err
+		}
+		return -delta, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(newValue), nil
+}
+
+var _ Cache = (*MemcachedCacheImpl)(nil)
@@ -0,0 +1,24 @@
+package encache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemcacheExpiration(t *testing.T) {
+	if got := memcacheExpiration(10 * time.Second); got != 10 {
+		t.Errorf("memcacheExpiration(10s) = %d, want 10", got)
+	}
+
+	if got := memcacheExpiration(29 * 24 * time.Hour); got != int32((29 * 24 * time.Hour).Seconds()) {
+		t.Errorf("memcacheExpiration(29d) = %d, want relative seconds", got)
+	}
+
+	longTTL := 45 * 24 * time.Hour
+	before := time.Now().Add(longTTL).Unix()
+	got := memcacheExpiration(longTTL)
+	after := time.Now().Add(longTTL).Unix()
+	if int64(got) < before || int64(got) > after {
+		t.Errorf("memcacheExpiration(45d) = %d, want an absolute unix timestamp in [%d, %d]", got, before, after)
+	}
+}
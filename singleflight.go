@@ -0,0 +1,138 @@
+package encache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"reflect"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// releaseLockScript deletes the lock only if it still holds the value we
+// set, so a lock that auto-expired and was re-acquired by another process
+// isn't yanked out from under it.
+var releaseLockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// randomLockToken returns a random value to identify this process's hold
+// on a distributed lock, so its release can be a compare-and-delete.
+func randomLockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// SingleflightCache wraps a Cache, collapsing concurrent misses for the
+// same key into one loader execution via GetOrLoad.
+type SingleflightCache struct {
+	Cache
+	group    singleflight.Group
+	distLock redis.UniversalClient
+	lockTTL  time.Duration
+}
+
+// SingleflightOption configures optional behaviour of a SingleflightCache.
+type SingleflightOption func(*SingleflightCache)
+
+// WithDistributedLock collapses misses across processes, not just within
+// one binary, by holding a "SET key value NX PX ttl" lock for the load.
+func WithDistributedLock(client redis.UniversalClient, lockTTL time.Duration) SingleflightOption {
+	return func(cacheImpl *SingleflightCache) {
+		cacheImpl.distLock = client
+		cacheImpl.lockTTL = lockTTL
+	}
+}
+
+// WithSingleflight wraps cache so concurrent misses for the same key
+// collapse into a single loader execution via GetOrLoad.
+func WithSingleflight(cache Cache, opts ...SingleflightOption) *SingleflightCache {
+	cacheImpl := &SingleflightCache{Cache: cache}
+	for _, opt := range opts {
+		opt(cacheImpl)
+	}
+	return cacheImpl
+}
+
+// GetOrLoad returns the cached value for key, calling loader and caching
+// its result with expiry on a miss.
+func (cacheImpl *SingleflightCache) GetOrLoad(key string, fType reflect.Type, expiry time.Duration, loader func() ([]reflect.Value, error)) ([]reflect.Value, error) {
+	if value, found, err := cacheImpl.Get(key, fType); err != nil {
+		return nil, err
+	} else if found {
+		return value, nil
+	}
+
+	value, err, _ := cacheImpl.group.Do(key, func() (interface{}, error) {
+		if cacheImpl.distLock != nil {
+			return cacheImpl.loadWithDistributedLock(key, fType, expiry, loader)
+		}
+		return cacheImpl.load(key, expiry, loader)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]reflect.Value), nil
+}
+
+func (cacheImpl *SingleflightCache) load(key string, expiry time.Duration, loader func() ([]reflect.Value, error)) ([]reflect.Value, error) {
+	value, err := loader()
+	if err != nil {
+		return nil, err
+	}
+	if err := cacheImpl.Set(key, value, expiry); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// loadWithDistributedLock holds the lock for key across the load, so only
+// one process recomputes it; the rest wait and re-read the cache.
+func (cacheImpl *SingleflightCache) loadWithDistributedLock(key string, fType reflect.Type, expiry time.Duration, loader func() ([]reflect.Value, error)) ([]reflect.Value, error) {
+	ctx := context.Background()
+	lockKey := "encache/lock/" + key
+
+	token, err := randomLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		acquired, err := cacheImpl.distLock.SetNX(ctx, lockKey, token, cacheImpl.lockTTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			break
+		}
+
+		if value, found, err := cacheImpl.Get(key, fType); err != nil {
+			return nil, err
+		} else if found {
+			return value, nil
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+	defer releaseLockScript.Run(ctx, cacheImpl.distLock, []string{lockKey}, token)
+
+	// Another process may have populated the cache and released the lock
+	// between our last failed SetNX and this one succeeding; re-check
+	// before recomputing so the lock still collapses the stampede.
+	if value, found, err := cacheImpl.Get(key, fType); err != nil {
+		return nil, err
+	} else if found {
+		return value, nil
+	}
+
+	return cacheImpl.load(key, expiry, loader)
+}
+
+var _ Cache = (*SingleflightCache)(nil)
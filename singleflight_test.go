@@ -0,0 +1,55 @@
+package encache
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflightCacheCollapsesConcurrentLoads(t *testing.T) {
+	cache := WithSingleflight(NewMapCacheImpl())
+
+	var calls int32
+	loader := func() ([]reflect.Value, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return intValues(42), nil
+	}
+
+	const goroutines = 10
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := cache.GetOrLoad("key", nil, time.Minute, loader)
+			if err != nil {
+				t.Errorf("GetOrLoad: %v", err)
+				return
+			}
+			if got := value[0].Interface(); got != 42 {
+				t.Errorf("GetOrLoad = %v, want 42", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("loader called %d times, want 1", calls)
+	}
+}
+
+func TestSingleflightCacheReturnsLoaderError(t *testing.T) {
+	cache := WithSingleflight(NewMapCacheImpl())
+
+	wantErr := errors.New("boom")
+	_, err := cache.GetOrLoad("key", nil, time.Minute, func() ([]reflect.Value, error) {
+		return nil, wantErr
+	})
+	if err != wantErr {
+		t.Errorf("GetOrLoad err = %v, want %v", err, wantErr)
+	}
+}